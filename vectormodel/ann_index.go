@@ -0,0 +1,267 @@
+package vectormodel
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type (
+	// IndexOptions configures the approximate nearest neighbor index built
+	// by BuildIndex. It currently implements an IVF (inverted file) index:
+	// item vectors are partitioned into NLists clusters by k-means, and a
+	// query only scores the items in the NProbe closest clusters. HNSW's
+	// M/EfConstruction/EfSearch knobs are kept here so that callers can
+	// tune a future graph-based index without another API break.
+	IndexOptions struct {
+		NLists         int // number of IVF clusters (inverted lists)
+		NProbe         int // number of clusters to search at query time
+		M              int // HNSW: number of neighbors per node (unused by IVF)
+		EfConstruction int // HNSW: construction-time candidate list size (unused by IVF)
+		EfSearch       int // HNSW: search-time candidate list size (unused by IVF)
+	}
+
+	// annIndex is an IVF index over the item factors of a VectorModel.
+	// Lists is keyed by cluster and holds document IDs rather than
+	// positional indexes into itemFactorsY, since NewVectorModel assigns
+	// those positions in (randomized) map iteration order: a doc ID is the
+	// only thing guaranteed to mean the same item across two separately
+	// built VectorModels.
+	annIndex struct {
+		Options   IndexOptions
+		Centroids [][]float64 // NLists x nFactors
+		Lists     [][]int     // NLists, each a slice of document IDs
+		NFactors  int
+	}
+)
+
+// DefaultIndexOptions returns reasonable defaults for a few hundred
+// thousand items.
+func DefaultIndexOptions() IndexOptions {
+	return IndexOptions{
+		NLists:         100,
+		NProbe:         8,
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+	}
+}
+
+// BuildIndex builds an in-memory approximate nearest neighbor index over
+// vm's item factors and attaches it to vm, so that ApproximateRecommend
+// can be used afterwards.
+func (vm *VectorModel) BuildIndex(opts IndexOptions) error {
+	if opts.NLists <= 0 {
+		return errors.New("IndexOptions.NLists must be positive")
+	}
+	if opts.NProbe <= 0 {
+		opts.NProbe = 1
+	}
+	nLists := opts.NLists
+	if nLists > len(vm.docIDs) {
+		nLists = len(vm.docIDs)
+	}
+
+	centroids, assignments := kMeans(vm.itemFactorsY, nLists, vm.nFactors)
+	lists := make([][]int, len(centroids))
+	for docIndex, cluster := range assignments {
+		lists[cluster] = append(lists[cluster], vm.docIDs[docIndex])
+	}
+
+	vm.index = &annIndex{
+		Options:   opts,
+		Centroids: centroids,
+		Lists:     lists,
+		NFactors:  vm.nFactors,
+	}
+	return nil
+}
+
+// ApproximateRecommend scores only the items in the NProbe closest
+// inverted lists to the user vector, instead of the full catalog, trading
+// recall for speed on large catalogs. BuildIndex must have been called
+// first.
+func (vm *VectorModel) ApproximateRecommend(interactions *Interactions, n int) (recommendations []DocumentScore, err error) {
+	if vm.index == nil {
+		return nil, errors.New("no index built; call BuildIndex first")
+	}
+	confidenceMap := vm.confidenceMap(interactions)
+	if len(confidenceMap) == 0 {
+		return nil, errors.New("No seen doc is in model")
+	}
+	userVec, err := vm.userVector(confidenceMap)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := vm.index.probe(userVec.RawVector().Data)
+	scored := make([]DocumentScore, 0, len(candidates))
+	for _, doc := range candidates {
+		if _, seen := (*interactions)[doc]; seen {
+			continue
+		}
+		docIndex, inModel := vm.docIndexes[doc]
+		if !inModel {
+			continue
+		}
+		row := vm.itemFactorsY.RawRowView(docIndex)
+		var score float64
+		for i, v := range row {
+			score += v * userVec.AtVec(i)
+		}
+		scored = append(scored, DocumentScore{doc, score})
+	}
+	sort.Sort(byDocScoreDesc(scored))
+	if len(scored) > n {
+		scored = scored[:n]
+	}
+	return scored, nil
+}
+
+// probe returns the document IDs belonging to the NProbe clusters whose
+// centroid is closest to query.
+func (idx *annIndex) probe(query []float64) []int {
+	type centroidDist struct {
+		cluster int
+		dist    float64
+	}
+	dists := make([]centroidDist, len(idx.Centroids))
+	for c, centroid := range idx.Centroids {
+		dists[c] = centroidDist{c, squaredDistance(centroid, query)}
+	}
+	sort.Slice(dists, func(i, j int) bool { return dists[i].dist < dists[j].dist })
+
+	nProbe := idx.Options.NProbe
+	if nProbe > len(dists) {
+		nProbe = len(dists)
+	}
+
+	var candidates []int
+	for _, cd := range dists[:nProbe] {
+		candidates = append(candidates, idx.Lists[cd.cluster]...)
+	}
+	return candidates
+}
+
+// SaveIndex persists vm's index to path, separately from the model itself.
+func (vm *VectorModel) SaveIndex(path string) error {
+	if vm.index == nil {
+		return errors.New("no index built; call BuildIndex first")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := gob.NewEncoder(w).Encode(vm.index); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// LoadIndex loads an index previously saved with SaveIndex and attaches it
+// to vm. It is rejected if it was not built over exactly vm's document IDs,
+// since an index built over a different (or differently constructed)
+// VectorModel would otherwise silently score the wrong items.
+func (vm *VectorModel) LoadIndex(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var index annIndex
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&index); err != nil {
+		return err
+	}
+	if index.NFactors != vm.nFactors {
+		return errors.New("index was built with a different number of factors")
+	}
+
+	indexedDocs := 0
+	for _, list := range index.Lists {
+		for _, doc := range list {
+			if _, inModel := vm.docIndexes[doc]; !inModel {
+				return fmt.Errorf("index was built over document %d, which is not in this model", doc)
+			}
+			indexedDocs++
+		}
+	}
+	if indexedDocs != len(vm.docIDs) {
+		return fmt.Errorf("index was built over %d documents, model has %d", indexedDocs, len(vm.docIDs))
+	}
+
+	vm.index = &index
+	return nil
+}
+
+// kMeans clusters the rows of y into k clusters using a fixed number of
+// Lloyd's algorithm iterations, returning the centroids and the cluster
+// assignment for each row.
+func kMeans(y *mat.Dense, k, nFactors int) ([][]float64, []int) {
+	rows, _ := y.Dims()
+	centroids := make([][]float64, k)
+	for c := 0; c < k; c++ {
+		// Deterministic seeding: stride through the rows so centroids start
+		// spread out across the dataset.
+		row := y.RawRowView((c * rows) / k)
+		centroid := make([]float64, nFactors)
+		copy(centroid, row)
+		centroids[c] = centroid
+	}
+
+	assignments := make([]int, rows)
+	const iterations = 10
+	for iter := 0; iter < iterations; iter++ {
+		for r := 0; r < rows; r++ {
+			row := y.RawRowView(r)
+			best, bestDist := 0, math.Inf(1)
+			for c, centroid := range centroids {
+				if d := squaredDistance(centroid, row); d < bestDist {
+					best, bestDist = c, d
+				}
+			}
+			assignments[r] = best
+		}
+
+		sums := make([][]float64, k)
+		counts := make([]int, k)
+		for c := range sums {
+			sums[c] = make([]float64, nFactors)
+		}
+		for r := 0; r < rows; r++ {
+			c := assignments[r]
+			row := y.RawRowView(r)
+			counts[c]++
+			for i, v := range row {
+				sums[c][i] += v
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				continue
+			}
+			for i := range centroids[c] {
+				centroids[c][i] = sums[c][i] / float64(counts[c])
+			}
+		}
+	}
+	return centroids, assignments
+}
+
+func squaredDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return sum
+}
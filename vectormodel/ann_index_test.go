@@ -0,0 +1,149 @@
+package vectormodel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApproximateRecommendFindsNearestNeighbor(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[0] = []float64{1, 0, 0}
+	docs[1] = []float64{0.9, 0.1, 0}
+	docs[2] = []float64{0, 1, 0}
+	docs[3] = []float64{0, 0, 1}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	opts := DefaultIndexOptions()
+	opts.NLists = 2
+	opts.NProbe = 2
+	if err := vm.BuildIndex(opts); err != nil {
+		t.Fatalf("Failed to build index: %s", err)
+	}
+
+	seenDocs := Interactions{0: 1}
+	recs, err := vm.ApproximateRecommend(&seenDocs, 1)
+	if err != nil {
+		t.Fatalf("Failed to recommend: %s", err)
+	}
+	if len(recs) != 1 || recs[0].DocumentID != 1 {
+		t.Errorf("Expected doc 1 as nearest neighbor, got %v", recs)
+	}
+}
+
+func TestSaveAndLoadIndex(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[0] = []float64{1, 0, 0}
+	docs[1] = []float64{0.9, 0.1, 0}
+	docs[2] = []float64{0, 1, 0}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	opts := DefaultIndexOptions()
+	opts.NLists = 2
+	opts.NProbe = 2 // probe every list, so this matches an exact scan
+	if err := vm.BuildIndex(opts); err != nil {
+		t.Fatalf("Failed to build index: %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	if err := vm.SaveIndex(path); err != nil {
+		t.Fatalf("Failed to save index: %s", err)
+	}
+
+	// A freshly constructed model assigns positional doc indexes in
+	// (randomized) map iteration order, so this exercises that the loaded
+	// index is keyed by document ID rather than position.
+	vm2, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create second vector model %s", err)
+	}
+	if err := vm2.LoadIndex(path); err != nil {
+		t.Fatalf("Failed to load index: %s", err)
+	}
+
+	seenDocs := Interactions{0: 1}
+	recs, err := vm2.ApproximateRecommend(&seenDocs, 2)
+	if err != nil {
+		t.Fatalf("Failed to recommend after loading index: %s", err)
+	}
+
+	expected, err := vm2.Recommend(&seenDocs, 2)
+	if err != nil {
+		t.Fatalf("Failed to compute exact recommendations: %s", err)
+	}
+	if len(recs) != len(expected) {
+		t.Fatalf("Expected %d recommendations, got %d", len(expected), len(recs))
+	}
+	for i := range expected {
+		if recs[i].DocumentID != expected[i].DocumentID {
+			t.Errorf("Mismatch at %d: got doc %d, want doc %d (exact scan)", i, recs[i].DocumentID, expected[i].DocumentID)
+		}
+	}
+}
+
+func TestLoadIndexRejectsUnknownDocuments(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[0] = []float64{1, 0, 0}
+	docs[1] = []float64{0, 1, 0}
+	docs[2] = []float64{0, 0, 1}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+	if err := vm.BuildIndex(DefaultIndexOptions()); err != nil {
+		t.Fatalf("Failed to build index: %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	if err := vm.SaveIndex(path); err != nil {
+		t.Fatalf("Failed to save index: %s", err)
+	}
+
+	docs2 := make(map[int][]float64)
+	docs2[0] = []float64{1, 0, 0}
+	docs2[1] = []float64{0, 1, 0}
+	docs2[99] = []float64{0, 0, 1} // disjoint from doc 2 in the original model
+	vm2, err := NewVectorModel(docs2, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create second vector model %s", err)
+	}
+	if err := vm2.LoadIndex(path); err == nil {
+		t.Errorf("Expected LoadIndex to reject an index built over different documents")
+	}
+}
+
+func TestLoadIndexRejectsMismatchedModel(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[0] = []float64{1, 0, 0}
+	docs[1] = []float64{0, 1, 0}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+	if err := vm.BuildIndex(DefaultIndexOptions()); err != nil {
+		t.Fatalf("Failed to build index: %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	if err := vm.SaveIndex(path); err != nil {
+		t.Fatalf("Failed to save index: %s", err)
+	}
+
+	docs2 := make(map[int][]float64)
+	docs2[0] = []float64{1, 0}
+	vm2, err := NewVectorModel(docs2, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create second vector model %s", err)
+	}
+	if err := vm2.LoadIndex(path); err == nil {
+		t.Errorf("Expected LoadIndex to reject a model with a different number of factors")
+	}
+
+	_ = os.Remove(path)
+}
@@ -0,0 +1,130 @@
+package vectormodel
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// BatchRecommend computes recommendations for a batch of user histories in
+// one call. The B per-user linear systems (each its own Cholesky
+// factorization of A = YtCuY + reg*I) are solved concurrently in a worker
+// pool, and the resulting user vectors are stacked into a single k x B
+// matrix U so the whole batch can be scored with one Y·U GEMM instead of B
+// independent MulVec calls. This is intended for offline scoring jobs that
+// serve many users at once: the worker pool amortizes solve latency across
+// CPU cores, and the single GEMM amortizes BLAS call overhead across the
+// batch.
+func (vm *VectorModel) BatchRecommend(histories []Interactions, n int) ([][]DocumentScore, error) {
+	scores, err := vm.batchScore(histories)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]DocumentScore, len(histories))
+	for u, interactions := range histories {
+		docScores := make([]DocumentScore, len(vm.docIDs))
+		for i, doc := range vm.docIDs {
+			score := scores.At(i, u)
+			if _, seen := interactions[doc]; seen {
+				score = -1
+			}
+			docScores[i] = DocumentScore{doc, score}
+		}
+		sort.Sort(byDocScoreDesc(docScores))
+		if len(docScores) > n {
+			docScores = docScores[:n]
+		}
+		results[u] = docScores
+	}
+	return results, nil
+}
+
+// BatchRank sorts, for each user, the candidates slice in place by score,
+// using the same batched scoring as BatchRecommend.
+func (vm *VectorModel) BatchRank(candidates [][]int, histories []Interactions) ([][]float64, error) {
+	if len(candidates) != len(histories) {
+		return nil, fmt.Errorf("candidates and histories must have the same length: %d != %d",
+			len(candidates), len(histories))
+	}
+
+	scores, err := vm.batchScore(histories)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]float64, len(histories))
+	for u, interactions := range histories {
+		candidateScores := make([]DocumentScore, len(candidates[u]))
+		for i, doc := range candidates[u] {
+			var score float64
+			if _, seen := interactions[doc]; seen {
+				score = -1
+			} else if docIndex, inModel := vm.docIndexes[doc]; inModel {
+				score = scores.At(docIndex, u)
+			}
+			candidateScores[i] = DocumentScore{doc, score}
+		}
+		sort.Sort(byDocScoreDesc(candidateScores))
+
+		userScores := make([]float64, len(candidateScores))
+		for i, ds := range candidateScores {
+			candidates[u][i] = ds.DocumentID
+			userScores[i] = ds.Score
+		}
+		results[u] = userScores
+	}
+	return results, nil
+}
+
+// batchScore solves the per-user linear systems for histories in a worker
+// pool bounded by GOMAXPROCS, then returns the nDocs x len(histories) score
+// matrix Y·U computed with a single GEMM.
+func (vm *VectorModel) batchScore(histories []Interactions) (*mat.Dense, error) {
+	u := mat.NewDense(vm.nFactors, len(histories), nil)
+	errs := make([]error, len(histories))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(histories) {
+		workers = len(histories)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for col := range jobs {
+				confidenceMap := vm.confidenceMap(&histories[col])
+				if len(confidenceMap) == 0 {
+					errs[col] = fmt.Errorf("No seen doc is in model for history %d", col)
+					continue
+				}
+				userVec, err := vm.userVector(confidenceMap)
+				if err != nil {
+					errs[col] = err
+					continue
+				}
+				u.SetCol(col, userVec.RawVector().Data)
+			}
+		}()
+	}
+	for col := range histories {
+		jobs <- col
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scores := mat.NewDense(len(vm.docIDs), len(histories), nil)
+	scores.Mul(vm.itemFactorsY, u)
+	return scores, nil
+}
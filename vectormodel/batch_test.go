@@ -0,0 +1,67 @@
+package vectormodel
+
+import "testing"
+
+func TestBatchRecommendMatchesRecommend(t *testing.T) {
+	confidence := 40.0
+	regularization := 0.01
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	docs[4567] = []float64{3, 2, 1}
+	vm, err := NewVectorModel(docs, confidence, regularization)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	seenDocs := Interactions{1234: 1}
+	expected, err := vm.Recommend(&seenDocs, 10)
+	if err != nil {
+		t.Fatalf("Failed to recommend %s", err)
+	}
+
+	batched, err := vm.BatchRecommend([]Interactions{seenDocs}, 10)
+	if err != nil {
+		t.Fatalf("Failed to batch recommend %s", err)
+	}
+	if len(batched) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(batched))
+	}
+	if len(batched[0]) != len(expected) {
+		t.Fatalf("Expected %d recommendations, got %d", len(expected), len(batched[0]))
+	}
+	for i, ds := range expected {
+		if batched[0][i].DocumentID != ds.DocumentID {
+			t.Errorf("Mismatch at %d: got %v, want %v", i, batched[0][i], ds)
+		}
+	}
+}
+
+func TestBatchRankMatchesRank(t *testing.T) {
+	confidence := 40.0
+	regularization := 0.01
+	docs := make(map[int][]float64)
+	docs[0] = []float64{1, 2, 3}
+	docs[1] = []float64{1, 2, 3}
+	docs[3] = []float64{3, 2, 1}
+	vm, err := NewVectorModel(docs, confidence, regularization)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	seenDocs := Interactions{0: 1}
+	items := []int{0, 1, 3}
+	if _, err := vm.Rank(&items, &seenDocs); err != nil {
+		t.Fatalf("Failed to rank %s", err)
+	}
+
+	batchItems := [][]int{{0, 1, 3}}
+	_, err = vm.BatchRank(batchItems, []Interactions{seenDocs})
+	if err != nil {
+		t.Fatalf("Failed to batch rank %s", err)
+	}
+	for i, doc := range items {
+		if batchItems[0][i] != doc {
+			t.Errorf("Mismatch at %d: got %d, want %d", i, batchItems[0][i], doc)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package vectormodel
+
+import "math"
+
+type (
+	// Interactions maps a document ID to the raw signal observed for it,
+	// e.g. a play count, a number of clicks, or simply 1 for a boolean
+	// "seen" event. ConfidenceFunc turns these raw values into the ALS
+	// confidence weights used by userVector.
+	Interactions map[int]float64
+
+	// ConfidenceFunc computes the ALS confidence weight for a raw
+	// interaction value r observed on docID.
+	ConfidenceFunc func(docID int, r float64) float64
+)
+
+// LinearConfidence implements the confidence scheme from "Collaborative
+// Filtering for Implicit Feedback Datasets": c = 1 + alpha*r.
+func LinearConfidence(alpha float64) ConfidenceFunc {
+	return func(docID int, r float64) float64 {
+		return 1 + alpha*r
+	}
+}
+
+// LogConfidence implements the logarithmic scaling variant from the same
+// paper: c = 1 + alpha*log(1 + r/eps), which grows the confidence more
+// slowly for large r than LinearConfidence.
+func LogConfidence(alpha, eps float64) ConfidenceFunc {
+	return func(docID int, r float64) float64 {
+		return 1 + alpha*math.Log(1+r/eps)
+	}
+}
+
+// BM25Confidence weighs each interaction by a BM25-style term that
+// discounts popular items, similarly to an IDF term: items with
+// popularity well above the average contribute a smaller confidence
+// boost per interaction than rare items do. popularity should map a
+// document ID to its total interaction count across all users.
+func BM25Confidence(k1, b float64, popularity map[int]float64) ConfidenceFunc {
+	var averagePopularity float64
+	for _, p := range popularity {
+		averagePopularity += p
+	}
+	if len(popularity) > 0 {
+		averagePopularity /= float64(len(popularity))
+	}
+
+	return func(docID int, r float64) float64 {
+		norm := k1
+		if averagePopularity > 0 {
+			norm = k1 * (1 - b + b*popularity[docID]/averagePopularity)
+		}
+		return 1 + (k1+1)*r/(norm+r)
+	}
+}
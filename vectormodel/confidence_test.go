@@ -0,0 +1,47 @@
+package vectormodel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLinearConfidence(t *testing.T) {
+	f := LinearConfidence(2.0)
+	if got := f(0, 3.0); math.Abs(got-7.0) > 1e-9 {
+		t.Errorf("LinearConfidence(2.0)(0, 3.0) = %f, want 7.0", got)
+	}
+}
+
+func TestLogConfidence(t *testing.T) {
+	f := LogConfidence(2.0, 1.0)
+	want := 1 + 2.0*math.Log(1+3.0)
+	if got := f(0, 3.0); math.Abs(got-want) > 1e-9 {
+		t.Errorf("LogConfidence(2.0, 1.0)(0, 3.0) = %f, want %f", got, want)
+	}
+}
+
+func TestBM25ConfidenceDiscountsPopularItems(t *testing.T) {
+	popularity := map[int]float64{1: 100, 2: 1}
+	f := BM25Confidence(1.2, 0.75, popularity)
+
+	popularConfidence := f(1, 5.0)
+	rareConfidence := f(2, 5.0)
+	if rareConfidence <= popularConfidence {
+		t.Errorf("Expected rare item to get a higher confidence boost than a popular one: rare=%f popular=%f",
+			rareConfidence, popularConfidence)
+	}
+}
+
+func TestSetConfidenceFuncOverridesDefault(t *testing.T) {
+	docs := map[int][]float64{1234: {1, 2, 3}}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+	vm.SetConfidenceFunc(LinearConfidence(1.0))
+
+	confidenceMap := vm.confidenceMap(&Interactions{1234: 5.0})
+	if got, want := confidenceMap[1234], 6.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("confidenceMap[1234] = %f, want %f", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package vectormodel
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Explain decomposes the score of candidate into the contribution of each
+// document in interactions, so that a caller can render "recommended
+// because you interacted with X, Y".
+//
+// Since x_u = A⁻¹ b and b = Σ_i c_i f_i, the score of a candidate j
+// decomposes as:
+//
+//	y_jᵀ x_u = Σ_i c_i · (y_jᵀ A⁻¹ f_i)
+//
+// We build A and factorize it with Cholesky, as userVector does, solve
+// A z = y_j once and compute each weight w_i = c_i · (z · f_i), i.e. one
+// factorization and one solve per call plus a dot product per interacted
+// document. The returned scores are the
+// interacted documents sorted by |w_i| descending, with Score set to w_i.
+func (vm *VectorModel) Explain(interactions *Interactions, candidate int) ([]DocumentScore, error) {
+	candidateIndex, candidateInModel := vm.docIndexes[candidate]
+	if !candidateInModel {
+		return nil, errors.New("candidate document not found in model")
+	}
+
+	confidenceMap := vm.confidenceMap(interactions)
+	if len(confidenceMap) == 0 {
+		return nil, errors.New("No seen doc is in model")
+	}
+
+	A, _ := vm.buildAAndB(confidenceMap)
+	ch, err := vm.factorizeA(A)
+	if err != nil {
+		return nil, err
+	}
+
+	yj := vm.itemFactorsY.RowView(candidateIndex)
+	var z mat.VecDense
+	if err := ch.SolveVec(&z, yj); err != nil {
+		return nil, err
+	}
+
+	contributions := make([]DocumentScore, 0, len(confidenceMap))
+	for doc, confidence := range confidenceMap {
+		index := vm.docIndexes[doc]
+		factor := vm.itemFactorsY.RowView(index)
+		w := confidence * mat.Dot(&z, factor)
+		contributions = append(contributions, DocumentScore{doc, w})
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		return math.Abs(contributions[i].Score) > math.Abs(contributions[j].Score)
+	})
+	return contributions, nil
+}
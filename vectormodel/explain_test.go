@@ -0,0 +1,83 @@
+package vectormodel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExplainContributionsSumToScore(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	docs[4567] = []float64{3, 2, 1}
+	docs[89] = []float64{2, 2, 2}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	interactions := Interactions{1234: 1, 89: 1}
+	contributions, err := vm.Explain(&interactions, 4567)
+	if err != nil {
+		t.Fatalf("Failed to explain: %s", err)
+	}
+	if len(contributions) != 2 {
+		t.Fatalf("Expected 2 contributions, got %d", len(contributions))
+	}
+
+	var total float64
+	for _, c := range contributions {
+		total += c.Score
+	}
+
+	confidenceMap := vm.confidenceMap(&interactions)
+	userVec, err := vm.userVector(confidenceMap)
+	if err != nil {
+		t.Fatalf("Failed to compute user vector: %s", err)
+	}
+	scoresVec := vm.scoresForUserVec(&userVec)
+	expected := scoresVec.At(vm.docIndexes[4567], 0)
+
+	if math.Abs(total-expected) > 1e-6 {
+		t.Errorf("Contributions sum to %f, want %f", total, expected)
+	}
+}
+
+func TestExplainSortsByAbsoluteContribution(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[0] = []float64{1, 0, 0}
+	docs[1] = []float64{0, 1, 0}
+	docs[2] = []float64{1, 0, 0}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	interactions := Interactions{0: 1, 1: 1}
+	contributions, err := vm.Explain(&interactions, 2)
+	if err != nil {
+		t.Fatalf("Failed to explain: %s", err)
+	}
+	if len(contributions) != 2 {
+		t.Fatalf("Expected 2 contributions, got %d", len(contributions))
+	}
+	if math.Abs(contributions[0].Score) < math.Abs(contributions[1].Score) {
+		t.Errorf("Expected contributions sorted by descending |score|, got %v", contributions)
+	}
+	if contributions[0].DocumentID != 0 {
+		t.Errorf("Expected doc 0 (aligned with candidate) to dominate, got %v", contributions[0])
+	}
+}
+
+func TestExplainRejectsUnknownCandidate(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	interactions := Interactions{1234: 1}
+	if _, err := vm.Explain(&interactions, 9999); err == nil {
+		t.Errorf("Expected error for candidate not in model")
+	}
+}
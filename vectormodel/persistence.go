@@ -0,0 +1,213 @@
+package vectormodel
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// modelMagic identifies a VectorModel binary file. modelVersion is bumped
+// whenever the on-disk layout changes incompatibly.
+const (
+	modelMagic   uint32 = 0x46414354 // "FACT"
+	modelVersion uint32 = 1
+)
+
+// Encoding selects the on-disk representation of the item factor matrix.
+type Encoding uint8
+
+const (
+	// Float64Encoding stores item factors as little-endian float64, the
+	// full precision the model was trained with.
+	Float64Encoding Encoding = iota
+	// Float32Encoding stores item factors as little-endian float32,
+	// halving the size of the factor matrix at the cost of precision,
+	// which is usually negligible at serving time.
+	Float32Encoding
+)
+
+// modelHeader is written first in the binary layout, in little-endian
+// byte order: magic, version, nFactors, nDocs, encoding, confidence,
+// regularization.
+type modelHeader struct {
+	Magic          uint32
+	Version        uint32
+	NFactors       uint32
+	NDocs          uint32
+	Encoding       uint8
+	Confidence     float64
+	Regularization float64
+}
+
+// WriteTo writes vm to w using a compact versioned binary layout: a fixed
+// header, the document IDs, and the item factor matrix, encoded as
+// float64. squaredItemFactorsYtY is not persisted; it is recomputed from
+// the factor matrix on load. Use WriteToEncoding to write item factors as
+// float32 instead, halving the size of the factor matrix on disk. ReadFrom
+// converts float32 factors back to float64, so a loaded model uses the same
+// memory with either encoding.
+func (vm *VectorModel) WriteTo(w io.Writer) (int64, error) {
+	return vm.WriteToEncoding(w, Float64Encoding)
+}
+
+// WriteToEncoding writes vm to w using the given item factor encoding.
+func (vm *VectorModel) WriteToEncoding(w io.Writer, encoding Encoding) (int64, error) {
+	var written int64
+
+	header := modelHeader{
+		Magic:          modelMagic,
+		Version:        modelVersion,
+		NFactors:       uint32(vm.nFactors),
+		NDocs:          uint32(len(vm.docIDs)),
+		Encoding:       uint8(encoding),
+		Confidence:     vm.confidence,
+		Regularization: vm.regularization,
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return written, err
+	}
+	written += int64(binary.Size(header))
+
+	docIDs := make([]int64, len(vm.docIDs))
+	for i, doc := range vm.docIDs {
+		docIDs[i] = int64(doc)
+	}
+	if err := binary.Write(w, binary.LittleEndian, docIDs); err != nil {
+		return written, err
+	}
+	written += int64(len(docIDs)) * 8
+
+	data := vm.itemFactorsY.RawMatrix().Data
+	switch encoding {
+	case Float64Encoding:
+		if err := binary.Write(w, binary.LittleEndian, data); err != nil {
+			return written, err
+		}
+		written += int64(len(data)) * 8
+	case Float32Encoding:
+		data32 := make([]float32, len(data))
+		for i, v := range data {
+			data32[i] = float32(v)
+		}
+		if err := binary.Write(w, binary.LittleEndian, data32); err != nil {
+			return written, err
+		}
+		written += int64(len(data32)) * 4
+	default:
+		return written, fmt.Errorf("unknown encoding: %d", encoding)
+	}
+
+	return written, nil
+}
+
+// ReadFrom reads a model previously written by WriteTo into vm, replacing
+// its contents. squaredItemFactorsYtY is recomputed from the loaded factor
+// matrix, and confidenceFunc is reset to the default driven by confidence.
+func (vm *VectorModel) ReadFrom(r io.Reader) (int64, error) {
+	var read int64
+
+	var header modelHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return read, err
+	}
+	read += int64(binary.Size(header))
+
+	if header.Magic != modelMagic {
+		return read, errors.New("not a VectorModel file")
+	}
+	if header.Version != modelVersion {
+		return read, fmt.Errorf("unsupported VectorModel version: %d", header.Version)
+	}
+
+	nFactors := int(header.NFactors)
+	nDocs := int(header.NDocs)
+
+	docIDs64 := make([]int64, nDocs)
+	if err := binary.Read(r, binary.LittleEndian, docIDs64); err != nil {
+		return read, err
+	}
+	read += int64(nDocs) * 8
+
+	data := make([]float64, nDocs*nFactors)
+	switch Encoding(header.Encoding) {
+	case Float64Encoding:
+		if err := binary.Read(r, binary.LittleEndian, data); err != nil {
+			return read, err
+		}
+		read += int64(len(data)) * 8
+	case Float32Encoding:
+		data32 := make([]float32, nDocs*nFactors)
+		if err := binary.Read(r, binary.LittleEndian, data32); err != nil {
+			return read, err
+		}
+		read += int64(len(data32)) * 4
+		for i, v := range data32 {
+			data[i] = float64(v)
+		}
+	default:
+		return read, fmt.Errorf("unknown encoding: %d", header.Encoding)
+	}
+
+	vm.confidence = header.Confidence
+	vm.regularization = header.Regularization
+	vm.nFactors = nFactors
+	vm.docIDs = make([]int, nDocs)
+	vm.docIndexes = make(map[int]int, nDocs)
+	for i, doc64 := range docIDs64 {
+		doc := int(doc64)
+		vm.docIDs[i] = doc
+		vm.docIndexes[doc] = i
+	}
+	vm.itemFactorsY = mat.NewDense(nDocs, nFactors, data)
+
+	var YtY mat.Dense
+	YtY.Mul(vm.itemFactorsY.T(), vm.itemFactorsY)
+	vm.squaredItemFactorsYtY = &YtY
+
+	vm.confidenceFunc = func(docID int, raw float64) float64 {
+		return vm.confidence
+	}
+	vm.index = nil
+
+	return read, nil
+}
+
+// SaveModel writes vm to path using WriteTo's binary layout.
+func (vm *VectorModel) SaveModel(path string) error {
+	return vm.SaveModelEncoding(path, Float64Encoding)
+}
+
+// SaveModelEncoding writes vm to path using the given item factor encoding.
+func (vm *VectorModel) SaveModelEncoding(path string, encoding Encoding) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if _, err := vm.WriteToEncoding(w, encoding); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// LoadModel reads a model previously saved with SaveModel or WriteTo.
+func LoadModel(path string) (*VectorModel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var vm VectorModel
+	if _, err := vm.ReadFrom(bufio.NewReader(f)); err != nil {
+		return nil, err
+	}
+	return &vm, nil
+}
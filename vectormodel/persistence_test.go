@@ -0,0 +1,122 @@
+package vectormodel
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteToReadFromRoundTrips(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	docs[4567] = []float64{3, 2, 1}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := vm.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write model: %s", err)
+	}
+
+	var loaded VectorModel
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("Failed to read model: %s", err)
+	}
+
+	if loaded.nFactors != vm.nFactors {
+		t.Errorf("nFactors mismatch: got %d, want %d", loaded.nFactors, vm.nFactors)
+	}
+	if loaded.confidence != vm.confidence || loaded.regularization != vm.regularization {
+		t.Errorf("confidence/regularization mismatch: got (%f, %f), want (%f, %f)",
+			loaded.confidence, loaded.regularization, vm.confidence, vm.regularization)
+	}
+	if len(loaded.docIDs) != len(vm.docIDs) {
+		t.Fatalf("docIDs length mismatch: got %d, want %d", len(loaded.docIDs), len(vm.docIDs))
+	}
+
+	seenDocs := Interactions{1234: 1}
+	expected, err := vm.Recommend(&seenDocs, 10)
+	if err != nil {
+		t.Fatalf("Failed to recommend on original model: %s", err)
+	}
+	actual, err := loaded.Recommend(&seenDocs, 10)
+	if err != nil {
+		t.Fatalf("Failed to recommend on loaded model: %s", err)
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("Recommendation length mismatch: got %d, want %d", len(actual), len(expected))
+	}
+	for i := range expected {
+		if actual[i].DocumentID != expected[i].DocumentID {
+			t.Errorf("Mismatch at %d: got %v, want %v", i, actual[i], expected[i])
+		}
+		if math.Abs(actual[i].Score-expected[i].Score) > 1e-9 {
+			t.Errorf("Score mismatch at %d: got %f, want %f", i, actual[i].Score, expected[i].Score)
+		}
+	}
+}
+
+func TestWriteToEncodingFloat32LosesLittlePrecision(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := vm.WriteToEncoding(&buf, Float32Encoding); err != nil {
+		t.Fatalf("Failed to write model: %s", err)
+	}
+
+	var loaded VectorModel
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("Failed to read model: %s", err)
+	}
+
+	a, b, c := loaded.itemFactorsY.At(0, 0), loaded.itemFactorsY.At(0, 1), loaded.itemFactorsY.At(0, 2)
+	if math.Abs(a-1) > 1e-6 || math.Abs(b-2) > 1e-6 || math.Abs(c-3) > 1e-6 {
+		t.Errorf("float32 round trip lost too much precision: got (%f, %f, %f)", a, b, c)
+	}
+}
+
+func TestSaveAndLoadModel(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	docs[4567] = []float64{3, 2, 1}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.bin")
+	if err := vm.SaveModel(path); err != nil {
+		t.Fatalf("Failed to save model: %s", err)
+	}
+
+	loaded, err := LoadModel(path)
+	if err != nil {
+		t.Fatalf("Failed to load model: %s", err)
+	}
+
+	seenDocs := Interactions{1234: 1}
+	recs, err := loaded.Recommend(&seenDocs, 10)
+	if err != nil {
+		t.Fatalf("Failed to recommend on loaded model: %s", err)
+	}
+	if len(recs) == 0 || recs[0].DocumentID != 4567 {
+		t.Errorf("Unexpected recommendations from loaded model: %v", recs)
+	}
+}
+
+func TestReadFromRejectsBadMagic(t *testing.T) {
+	var loaded VectorModel
+	// Long enough to hold a whole header, so ReadFrom reaches the magic check.
+	contents := bytes.Repeat([]byte("not a model file"), 4)
+	if _, err := loaded.ReadFrom(bytes.NewReader(contents)); err == nil {
+		t.Errorf("Expected error for invalid file contents")
+	}
+}
@@ -0,0 +1,140 @@
+package vectormodel
+
+import (
+	"errors"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// UserState tracks a single user's running ALS solution so that new
+// interactions can be folded in without recomputing the full linear system
+// from scratch. It keeps A⁻¹ = (YtCuY + regularization*I)⁻¹ and
+// b = YtCuPu up to date via Sherman-Morrison rank-1 updates, so that
+// Observe and Forget cost O(nFactors²) instead of the O(|history|·nFactors²)
+// that a from-scratch userVector call would require.
+type UserState struct {
+	vm       *VectorModel
+	aInverse *mat.Dense
+	b        *mat.VecDense
+	history  map[int]float64
+}
+
+// NewUserState creates an empty UserState for vm, initialized to the prior
+// A⁻¹ = (YtY + regularization*I)⁻¹ and b = 0, i.e. a user with no history.
+func (vm *VectorModel) NewUserState() (*UserState, error) {
+	A, b := vm.buildAAndB(map[int]float64{})
+
+	aInverse := mat.NewDense(vm.nFactors, vm.nFactors, nil)
+	if err := aInverse.Inverse(A); err != nil {
+		return nil, err
+	}
+
+	return &UserState{
+		vm:       vm,
+		aInverse: aInverse,
+		b:        b,
+		history:  make(map[int]float64),
+	}, nil
+}
+
+// Observe folds a single interaction with docID into the user's state,
+// using the Sherman-Morrison formula to update A⁻¹ in place:
+//
+//	A⁻¹ <- A⁻¹ - ((c-1) A⁻¹ f fᵀ A⁻¹) / (1 + (c-1) fᵀ A⁻¹ f)
+//	b   <- b + c*f
+//
+// where f is the item factor vector for docID and c is confidence. A
+// document can only contribute one confidence to A and b at a time, so
+// observing an already-observed docID first undoes its previous
+// contribution before applying the new one, rather than accumulating both.
+func (s *UserState) Observe(docID int, confidence float64) error {
+	index, docFound := s.vm.docIndexes[docID]
+	if !docFound {
+		return errors.New("document not found in model")
+	}
+	f := s.vm.itemFactorsY.RowView(index)
+
+	if previousConfidence, alreadyObserved := s.history[docID]; alreadyObserved {
+		if err := s.rankOneUpdate(f, -(previousConfidence - 1)); err != nil {
+			return err
+		}
+		s.b.AddScaledVec(s.b, -previousConfidence, f)
+	}
+
+	if err := s.rankOneUpdate(f, confidence-1); err != nil {
+		return err
+	}
+	s.b.AddScaledVec(s.b, confidence, f)
+	s.history[docID] = confidence
+	return nil
+}
+
+// Forget removes a previously observed document from the user's state,
+// applying the symmetric rank-1 downdate that exactly reverses the
+// confidence last applied by Observe for docID.
+func (s *UserState) Forget(docID int) error {
+	confidence, observed := s.history[docID]
+	if !observed {
+		return errors.New("document was not observed")
+	}
+	index, docFound := s.vm.docIndexes[docID]
+	if !docFound {
+		return errors.New("document not found in model")
+	}
+	f := s.vm.itemFactorsY.RowView(index)
+	if err := s.rankOneUpdate(f, -(confidence - 1)); err != nil {
+		return err
+	}
+	s.b.AddScaledVec(s.b, -confidence, f)
+	delete(s.history, docID)
+	return nil
+}
+
+// rankOneUpdate applies the Sherman-Morrison update for A <- A + weight*f*fᵀ
+// to the stored A⁻¹.
+func (s *UserState) rankOneUpdate(f mat.Vector, weight float64) error {
+	if weight == 0 {
+		return nil
+	}
+	var aInvF mat.VecDense
+	aInvF.MulVec(s.aInverse, f)
+
+	denominator := 1 + weight*mat.Dot(f, &aInvF)
+	if denominator == 0 {
+		return errors.New("Sherman-Morrison update is singular")
+	}
+
+	var numerator mat.Dense
+	numerator.Outer(weight/denominator, &aInvF, &aInvF)
+	s.aInverse.Sub(s.aInverse, &numerator)
+	return nil
+}
+
+// userVector solves x = A⁻¹ b for the current state.
+func (s *UserState) userVector() mat.VecDense {
+	var x mat.VecDense
+	x.MulVec(s.aInverse, s.b)
+	return x
+}
+
+// TopN returns the n highest scoring documents for the user's current
+// state, excluding documents already observed.
+func (s *UserState) TopN(n int) []DocumentScore {
+	userVec := s.userVector()
+	scoresVec := s.vm.scoresForUserVec(&userVec)
+
+	candidateScores := make([]DocumentScore, 0, len(s.vm.docIDs))
+	for _, doc := range s.vm.docIDs {
+		if _, seen := s.history[doc]; seen {
+			continue
+		}
+		docIndex := s.vm.docIndexes[doc]
+		candidateScores = append(candidateScores, DocumentScore{doc, scoresVec.At(docIndex, 0)})
+	}
+	sort.Sort(byDocScoreDesc(candidateScores))
+	if len(candidateScores) > n {
+		candidateScores = candidateScores[:n]
+	}
+	return candidateScores
+}
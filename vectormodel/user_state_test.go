@@ -0,0 +1,148 @@
+package vectormodel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUserStateMatchesFromScratchUserVector(t *testing.T) {
+	regularization := 0.01
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	docs[666] = []float64{0.5, 1.5, 2.5}
+	vm, err := NewVectorModel(docs, 40.0, regularization)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	state, err := vm.NewUserState()
+	if err != nil {
+		t.Fatalf("Failed to create user state: %s", err)
+	}
+	if err := state.Observe(1234, 40.0); err != nil {
+		t.Fatalf("Failed to observe doc: %s", err)
+	}
+	if err := state.Observe(666, 1.0); err != nil {
+		t.Fatalf("Failed to observe doc: %s", err)
+	}
+
+	expected, err := vm.userVector(map[int]float64{1234: 40.0, 666: 1.0})
+	if err != nil {
+		t.Fatalf("Failed to compute expected user vector: %s", err)
+	}
+
+	actual := state.userVector()
+	for i := 0; i < vm.nFactors; i++ {
+		if math.Abs(actual.At(i, 0)-expected.At(i, 0)) > 1e-8 {
+			t.Fatalf("Mismatch at index %d: got %f, want %f", i, actual.At(i, 0), expected.At(i, 0))
+		}
+	}
+}
+
+func TestUserStateForgetUndoesObserve(t *testing.T) {
+	regularization := 0.01
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	docs[666] = []float64{0.5, 1.5, 2.5}
+	vm, err := NewVectorModel(docs, 40.0, regularization)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	state, err := vm.NewUserState()
+	if err != nil {
+		t.Fatalf("Failed to create user state: %s", err)
+	}
+	if err := state.Observe(1234, 40.0); err != nil {
+		t.Fatalf("Failed to observe doc: %s", err)
+	}
+	if err := state.Observe(666, 1.0); err != nil {
+		t.Fatalf("Failed to observe doc: %s", err)
+	}
+	if err := state.Forget(666); err != nil {
+		t.Fatalf("Failed to forget doc: %s", err)
+	}
+
+	expected, err := vm.userVector(map[int]float64{1234: 40.0})
+	if err != nil {
+		t.Fatalf("Failed to compute expected user vector: %s", err)
+	}
+
+	actual := state.userVector()
+	for i := 0; i < vm.nFactors; i++ {
+		if math.Abs(actual.At(i, 0)-expected.At(i, 0)) > 1e-8 {
+			t.Fatalf("Mismatch at index %d: got %f, want %f", i, actual.At(i, 0), expected.At(i, 0))
+		}
+	}
+}
+
+func TestUserStateObserveTwiceReplacesConfidence(t *testing.T) {
+	regularization := 0.01
+	docs := make(map[int][]float64)
+	docs[1234] = []float64{1, 2, 3}
+	docs[666] = []float64{0.5, 1.5, 2.5}
+	vm, err := NewVectorModel(docs, 40.0, regularization)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	state, err := vm.NewUserState()
+	if err != nil {
+		t.Fatalf("Failed to create user state: %s", err)
+	}
+	if err := state.Observe(1234, 40.0); err != nil {
+		t.Fatalf("Failed to observe doc: %s", err)
+	}
+	// Observing the same doc again should replace, not accumulate, its
+	// contribution to A and b.
+	if err := state.Observe(1234, 40.0); err != nil {
+		t.Fatalf("Failed to re-observe doc: %s", err)
+	}
+	if err := state.Observe(666, 1.0); err != nil {
+		t.Fatalf("Failed to observe doc: %s", err)
+	}
+	if err := state.Forget(666); err != nil {
+		t.Fatalf("Failed to forget doc: %s", err)
+	}
+
+	expected, err := vm.userVector(map[int]float64{1234: 40.0})
+	if err != nil {
+		t.Fatalf("Failed to compute expected user vector: %s", err)
+	}
+
+	actual := state.userVector()
+	for i := 0; i < vm.nFactors; i++ {
+		if math.Abs(actual.At(i, 0)-expected.At(i, 0)) > 1e-8 {
+			t.Fatalf("Mismatch at index %d: got %f, want %f", i, actual.At(i, 0), expected.At(i, 0))
+		}
+	}
+}
+
+func TestUserStateTopNExcludesObserved(t *testing.T) {
+	docs := make(map[int][]float64)
+	docs[0] = []float64{1, 2, 3}
+	docs[1] = []float64{1, 2, 3.01}
+	docs[2] = []float64{3, 2, 1}
+	vm, err := NewVectorModel(docs, 40.0, 0.01)
+	if err != nil {
+		t.Fatalf("Failed to create vector model %s", err)
+	}
+
+	state, err := vm.NewUserState()
+	if err != nil {
+		t.Fatalf("Failed to create user state: %s", err)
+	}
+	if err := state.Observe(0, 40.0); err != nil {
+		t.Fatalf("Failed to observe doc: %s", err)
+	}
+
+	top := state.TopN(10)
+	if len(top) != 2 {
+		t.Fatalf("Expected 2 recommendations, got %d", len(top))
+	}
+	for _, ds := range top {
+		if ds.DocumentID == 0 {
+			t.Fatalf("TopN should not include observed document")
+		}
+	}
+}
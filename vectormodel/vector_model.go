@@ -25,6 +25,8 @@ type (
 		nFactors              int
 		itemFactorsY          *mat.Dense
 		squaredItemFactorsYtY *mat.Dense
+		index                 *annIndex
+		confidenceFunc        ConfidenceFunc
 	}
 
 	// DocumentScore is the result of a recommendation
@@ -67,12 +69,26 @@ func NewVectorModel(documents map[int][]float64, confidence, regularization floa
 	YtY.Mul(vm.itemFactorsY.T(), vm.itemFactorsY)
 	vm.squaredItemFactorsYtY = &YtY
 
+	// By default every interaction counts as the model's flat confidence,
+	// regardless of its raw value, matching the original "seen or not"
+	// semantics. Use SetConfidenceFunc to weigh interactions by count.
+	vm.confidenceFunc = func(docID int, raw float64) float64 {
+		return vm.confidence
+	}
+
 	return &vm, nil
 }
 
+// SetConfidenceFunc overrides how raw interaction values are turned into
+// ALS confidence weights. See LinearConfidence, LogConfidence and
+// BM25Confidence for built-in transforms.
+func (vm *VectorModel) SetConfidenceFunc(f ConfidenceFunc) {
+	vm.confidenceFunc = f
+}
+
 // Rank sorts a list of candidate assets for a given user history
-func (vm *VectorModel) Rank(candidates *[]int, seenDocs *map[int]bool) (scores []float64, err error) {
-	candidateScores, err := vm.scoreCandidates(candidates, seenDocs)
+func (vm *VectorModel) Rank(candidates *[]int, interactions *Interactions) (scores []float64, err error) {
+	candidateScores, err := vm.scoreCandidates(candidates, interactions)
 	if err != nil {
 		return nil, err
 	}
@@ -85,8 +101,8 @@ func (vm *VectorModel) Rank(candidates *[]int, seenDocs *map[int]bool) (scores [
 }
 
 // Recommend returns a list of recommendedDocs and a list of scores
-func (vm *VectorModel) Recommend(seenDocs *map[int]bool, n int) (recommendations []DocumentScore, err error) {
-	recommendations, err = vm.scoreCandidates(&vm.docIDs, seenDocs)
+func (vm *VectorModel) Recommend(interactions *Interactions, n int) (recommendations []DocumentScore, err error) {
+	recommendations, err = vm.scoreCandidates(&vm.docIDs, interactions)
 	if err != nil {
 		return nil, err
 	}
@@ -96,11 +112,11 @@ func (vm *VectorModel) Recommend(seenDocs *map[int]bool, n int) (recommendations
 	return recommendations, nil
 }
 
-func (vm *VectorModel) scoreCandidates(candidates *[]int, seenDocs *map[int]bool) (recommendations []DocumentScore, err error) {
-	confidenceMap := vm.confidenceMap(seenDocs)
+func (vm *VectorModel) scoreCandidates(candidates *[]int, interactions *Interactions) (recommendations []DocumentScore, err error) {
+	confidenceMap := vm.confidenceMap(interactions)
 	if len(confidenceMap) == 0 {
 		return nil, fmt.Errorf("No seen doc is in model. History: %d Model: %d",
-			len(*seenDocs), len(vm.docIndexes))
+			len(*interactions), len(vm.docIndexes))
 	}
 	userVec, err := vm.userVector(confidenceMap)
 	if err != nil {
@@ -110,7 +126,7 @@ func (vm *VectorModel) scoreCandidates(candidates *[]int, seenDocs *map[int]bool
 	candidateScores := make([]DocumentScore, len(*candidates))
 	for i, doc := range *candidates {
 		var score float64
-		if _, docAlreadySeen := (*seenDocs)[doc]; docAlreadySeen {
+		if _, docAlreadySeen := (*interactions)[doc]; docAlreadySeen {
 			score = -1
 		} else if docIndex, docInModel := vm.docIndexes[doc]; !docInModel {
 			score = 0
@@ -123,11 +139,13 @@ func (vm *VectorModel) scoreCandidates(candidates *[]int, seenDocs *map[int]bool
 	return candidateScores, nil
 }
 
-func (vm *VectorModel) confidenceMap(seenDocs *map[int]bool) map[int]float64 {
+// confidenceMap applies vm.confidenceFunc to each raw interaction value,
+// dropping documents that are not part of the model.
+func (vm *VectorModel) confidenceMap(interactions *Interactions) map[int]float64 {
 	confidenceMap := make(map[int]float64)
-	for doc := range *seenDocs {
+	for doc, raw := range *interactions {
 		if _, inModel := vm.docIndexes[doc]; inModel {
-			confidenceMap[doc] = vm.confidence
+			confidenceMap[doc] = vm.confidenceFunc(doc, raw)
 		}
 	}
 	return confidenceMap
@@ -140,7 +158,24 @@ func (vm *VectorModel) userVector(confidenceMap map[int]float64) (mat.VecDense,
 
 	// We solve the following linear equation:
 	// Xu = (YtCuY + regularization*I)i^-1 * YtYCuPu
+	A, b := vm.buildAAndB(confidenceMap)
+
+	var x mat.VecDense
+	// We could just solve the matrix by calling the next line, but
+	// A is positively defined, so we can use the Cholesky solver
+	// err := x.SolveVec(A, b)
+
+	ch, err := vm.factorizeA(A)
+	if err != nil {
+		return x, err
+	}
+	err = ch.SolveVec(&x, b)
+	return x, err
+}
 
+// buildAAndB assembles A = YtCuY + reg*I and b = YtCuPu for a given set of
+// consumed documents and their confidences.
+func (vm *VectorModel) buildAAndB(confidenceMap map[int]float64) (*mat.Dense, *mat.VecDense) {
 	// A = YtCuY + reg * I = YtY + reg * I + Yt(Cu - I)Y
 	// We initialize A to YtY + reg * I  and sum the last term for each doc
 	var A mat.Dense
@@ -166,17 +201,17 @@ func (vm *VectorModel) userVector(confidenceMap map[int]float64) (mat.VecDense,
 		b.AddScaledVec(b, confidence, factor)
 	}
 
-	var x mat.VecDense
-	// We could just solve the matrix by calling the next line, but
-	// A is positively defined, so we can use the Cholesky solver
-	// err := x.SolveVec(&A, b)
+	return &A, b
+}
 
+// factorizeA runs the Cholesky factorization of A, which is positive
+// definite by construction.
+func (vm *VectorModel) factorizeA(A *mat.Dense) (*mat.Cholesky, error) {
 	var ch mat.Cholesky
-	if ok := ch.Factorize(&unsafeSymmetric{A, vm.nFactors}); !ok {
-		return x, errors.New("Failed to run Cholesky factorization")
+	if ok := ch.Factorize(&unsafeSymmetric{*A, vm.nFactors}); !ok {
+		return nil, errors.New("Failed to run Cholesky factorization")
 	}
-	err := ch.SolveVec(&x, b)
-	return x, err
+	return &ch, nil
 }
 
 // scoresForUserVec returns a vector with scores given set of consumed documents
@@ -176,7 +176,7 @@ func TestRecommend(t *testing.T) {
 		t.Fatalf("Failed to create vector model %s", err)
 	}
 
-	seenDocs := map[int]bool{1234: true}
+	seenDocs := Interactions{1234: 1}
 	n := 10
 
 	recommendations, err := vm.Recommend(&seenDocs, n)
@@ -228,7 +228,7 @@ func TestRecommendReturnsTopItems(t *testing.T) {
 		t.Fatalf("Failed to create vector model %s", err)
 	}
 
-	seenDocs := map[int]bool{0: true}
+	seenDocs := Interactions{0: 1}
 	n := 3
 	recs, err := vm.Recommend(&seenDocs, n)
 	if err != nil {
@@ -256,9 +256,9 @@ func TestRankSortsTopItems(t *testing.T) {
 		t.Fatalf("Failed to create vector model %s", err)
 	}
 
-	seenDocs := map[int]bool{0: true}
+	seenDocs := Interactions{0: 1}
 	items := []int{0, 1, 3, 10}
-	err = vm.Rank(&items, &seenDocs)
+	_, err = vm.Rank(&items, &seenDocs)
 	if err != nil {
 		t.Fatalf("Failed to recommend %s", err)
 	}